@@ -0,0 +1,344 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package backends implements bind.ContractBackend on top of a in-memory evm state.
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	XDPoSChain "github.com/XinFinOrg/XDPoSChain"
+	"github.com/XinFinOrg/XDPoSChain/accounts/abi"
+	"github.com/XinFinOrg/XDPoSChain/accounts/abi/bind"
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/common/math"
+	"github.com/XinFinOrg/XDPoSChain/consensus/ethash"
+	"github.com/XinFinOrg/XDPoSChain/core"
+	"github.com/XinFinOrg/XDPoSChain/core/rawdb"
+	"github.com/XinFinOrg/XDPoSChain/core/state"
+	"github.com/XinFinOrg/XDPoSChain/core/types"
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+	"github.com/XinFinOrg/XDPoSChain/ethdb"
+	"github.com/XinFinOrg/XDPoSChain/params"
+)
+
+// This nil assertion ensures at compile time that SimulatedBackend implements bind.ContractBackend.
+var _ bind.ContractBackend = (*SimulatedBackend)(nil)
+
+var (
+	errBlockNumberUnsupported = errors.New("simulatedBackend cannot access blocks other than the latest block")
+)
+
+// SimulatedBackend implements bind.ContractBackend, simulating a blockchain in
+// the background. Its main purpose is to allow for easy testing of contract
+// bindings.
+type SimulatedBackend struct {
+	mu sync.Mutex
+
+	database   ethdb.Database   // In memory database backing the blockchain
+	blockchain *core.BlockChain // Ethereum blockchain to handle the consensus
+
+	pendingBlock *types.Block   // Currently pending block that will be imported on request
+	pendingState *state.StateDB // Currently pending state that will be the active on request
+
+	config   *params.ChainConfig
+	vmConfig vm.Config // used by callContract; see SetTracer
+}
+
+// NewXDCSimulatedBackend creates a new binding backend using a simulated blockchain
+// for testing purposes.
+func NewXDCSimulatedBackend(alloc core.GenesisAlloc, gasLimit uint64, config *params.ChainConfig) *SimulatedBackend {
+	if config == nil {
+		config = params.TestXDPoSMockChainConfig
+	}
+	database := rawdb.NewMemoryDatabase()
+	genesis := core.Genesis{Config: config, GasLimit: gasLimit, Alloc: alloc}
+	genesis.MustCommit(database)
+	blockchain, _ := core.NewBlockChain(database, nil, config, ethash.NewFaker(), vm.Config{}, nil)
+
+	backend := &SimulatedBackend{database: database, blockchain: blockchain, config: config}
+	backend.rollback()
+	return backend
+}
+
+// Close terminates the underlying blockchain's teardown procedure.
+func (b *SimulatedBackend) Close() error {
+	return nil
+}
+
+// Commit imports all the pending transactions as a single block and starts a
+// fresh new state.
+func (b *SimulatedBackend) Commit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blockchain.InsertChain(types.Blocks{b.pendingBlock})
+	b.rollback()
+}
+
+// rollback aborts all pending transactions, reverting to the last committed state.
+func (b *SimulatedBackend) rollback() {
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.blockchain.Engine(), b.database, 1, func(int, *core.BlockGen) {})
+	statedb, _ := b.blockchain.State()
+
+	b.pendingBlock = blocks[0]
+	b.pendingState = statedb.Copy()
+}
+
+// signer returns the most permissive signer for the block the pending state
+// is building on top of, preferring typed-transaction aware signers so that
+// EIP-2718/EIP-2930 envelopes keep working once they activate.
+func (b *SimulatedBackend) signer() types.Signer {
+	return types.MakeSigner(b.config, b.pendingBlock.Number())
+}
+
+// CodeAt returns the code associated with a certain account in the blockchain.
+func (b *SimulatedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	statedb, _ := b.blockchain.State()
+	return statedb.GetCode(contract), nil
+}
+
+// PendingCodeAt returns the code associated with an account in the pending state.
+func (b *SimulatedBackend) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pendingState.GetCode(contract), nil
+}
+
+// CallContract executes a contract call against the latest committed state.
+func (b *SimulatedBackend) CallContract(ctx context.Context, call XDPoSChain.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	statedb, _ := b.blockchain.State()
+	res, err := b.callContract(ctx, call, b.blockchain.CurrentBlock(), statedb)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed() {
+		return nil, revertError(res)
+	}
+	return res.Return(), nil
+}
+
+// PendingCallContract executes a contract call against the pending state.
+func (b *SimulatedBackend) PendingCallContract(ctx context.Context, call XDPoSChain.CallMsg) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.pendingState.RevertToSnapshot(b.pendingState.Snapshot())
+
+	res, err := b.callContract(ctx, call, b.pendingBlock, b.pendingState)
+	if err != nil {
+		return nil, err
+	}
+	if res.Failed() {
+		return nil, revertError(res)
+	}
+	return res.Return(), nil
+}
+
+// PendingNonceAt returns the nonce of the account in the pending state.
+func (b *SimulatedBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pendingState.GetOrNewStateObject(account).Nonce(), nil
+}
+
+// SuggestGasPrice implements ContractTransactor.SuggestGasPrice. Since the simulated
+// chain doesn't have miners, we just return a gas price of 1 for any call.
+func (b *SimulatedBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+// SuggestGasTipCap implements ContractTransactor.SuggestGasTipCap. Since the simulated
+// chain doesn't have miners, we just return a gas tip of 1 for any call.
+func (b *SimulatedBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+// EstimateGas executes the requested code against the currently pending block/state and
+// returns the used amount of gas, taking the EIP-2930 access list (if any) into account
+// for the intrinsic gas calculation.
+func (b *SimulatedBackend) EstimateGas(ctx context.Context, call XDPoSChain.CallMsg) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Determine the lowest and highest possible gas limits to binary search in between
+	var (
+		lo  uint64 = params.TxGas - 1
+		hi  uint64
+		cap uint64
+	)
+	if call.Gas >= params.TxGas {
+		hi = call.Gas
+	} else {
+		hi = b.pendingBlock.GasLimit()
+	}
+	cap = hi
+
+	// Create a helper to check if a gas allowance results in an executable transaction
+	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
+		call.Gas = gas
+
+		snapshot := b.pendingState.Snapshot()
+		res, err := b.callContract(ctx, call, b.pendingBlock, b.pendingState)
+		b.pendingState.RevertToSnapshot(snapshot)
+
+		if err != nil {
+			if errors.Is(err, core.ErrIntrinsicGas) {
+				return true, nil, nil // Special case, raise gas limit
+			}
+			return true, nil, err // Bail out
+		}
+		return res.Failed(), res, nil
+	}
+	// Execute the binary search and hone in on an executable gas limit
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		failed, _, err := executable(mid)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// Reject the transaction as invalid if it still fails at the highest allowance
+	if hi == cap {
+		failed, result, err := executable(hi)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			if result != nil && result.Err != vm.ErrOutOfGas {
+				if len(result.Revert()) > 0 {
+					reason, errUnpack := abi.UnpackRevert(result.Revert())
+					if errUnpack == nil {
+						return 0, fmt.Errorf("always failing transaction (%v) (%v)", result.Err, reason)
+					}
+				}
+				return 0, fmt.Errorf("always failing transaction (%v)", result.Err)
+			}
+			return 0, fmt.Errorf("gas required exceeds allowance (%d)", cap)
+		}
+	}
+	return hi, nil
+}
+
+// callContract implements common code between CallContract and EstimateGas.
+func (b *SimulatedBackend) callContract(ctx context.Context, call XDPoSChain.CallMsg, block *types.Block, statedb *state.StateDB) (*core.ExecutionResult, error) {
+	// Ensure message is initialized properly.
+	if call.GasPrice == nil {
+		call.GasPrice = big.NewInt(1)
+	}
+	if call.Gas == 0 {
+		call.Gas = 50000000
+	}
+	if call.Value == nil {
+		call.Value = new(big.Int)
+	}
+	// Set infinite balance to the fake caller account.
+	from := statedb.GetOrNewStateObject(call.From)
+	from.SetBalance(math.MaxBig256)
+	// Execute the call.
+	msg := types.NewMessage(call.From, call.To, 0, call.Value, call.Gas, call.GasPrice, call.Data, call.AccessList, false)
+
+	evmContext := core.NewEVMContext(msg, block.Header(), b.blockchain, nil)
+	// Create a new environment which holds all relevant information
+	// about the transaction and calling mechanisms.
+	vmEnv := vm.NewEVM(evmContext, statedb, b.config, b.vmConfig)
+	gaspool := new(core.GasPool).AddGas(math.MaxUint64)
+
+	return core.ApplyMessage(vmEnv, msg, gaspool)
+}
+
+// SetTracer attaches logger as the vm.EVMLogger driven by every subsequent
+// CallContract, PendingCallContract and EstimateGas execution, so a tracer
+// (see eth/tracers) can observe a simulated call the same way it would a
+// real one. Pass nil to detach. It only covers calls executed through
+// callContract above: committing a transaction via SendTransaction/Commit
+// still replays it through the blockchain's own block processor, which
+// hardcodes vm.Config{} and has no equivalent hook.
+func (b *SimulatedBackend) SetTracer(logger vm.EVMLogger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.vmConfig = vm.Config{Debug: logger != nil, Tracer: logger}
+}
+
+// SendTransaction updates the pending block to include the given transaction, resolving
+// the correct signer for it (legacy, EIP-2930 access-list, or any later typed envelope)
+// via types.MakeSigner rather than assuming EIP-155.
+func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sender, err := types.Sender(b.signer(), tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %v", err)
+	}
+	nonce := b.pendingState.GetNonce(sender)
+	if tx.Nonce() != nonce {
+		return fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce)
+	}
+
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.blockchain.Engine(), b.database, 1, func(number int, block *core.BlockGen) {
+		for _, pending := range b.pendingBlock.Transactions() {
+			block.AddTxWithChain(b.blockchain, pending)
+		}
+		block.AddTxWithChain(b.blockchain, tx)
+	})
+	statedb, _ := b.blockchain.State()
+
+	b.pendingBlock = blocks[0]
+	b.pendingState = statedb.Copy()
+	return nil
+}
+
+// revertError is an API error that encompasses an EVM revert with its corresponding
+// output data.
+type revertErrorWrapper struct {
+	error
+	reason string // revert reason hex encoded
+}
+
+// revertError wraps the result of a reverted call into an error carrying the decoded
+// revert reason, mirroring the "always failing transaction (revert reason)" messages
+// the RPC layer already produces for legacy calls.
+func revertError(result *core.ExecutionResult) error {
+	reason, errUnpack := abi.UnpackRevert(result.Revert())
+	err := errors.New("execution reverted")
+	if errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertErrorWrapper{
+		error:  err,
+		reason: common.Bytes2Hex(result.Revert()),
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/XinFinOrg/XDPoSChain/accounts/abi/bind"
 	"github.com/XinFinOrg/XDPoSChain/common"
 	"github.com/XinFinOrg/XDPoSChain/core"
+	"github.com/XinFinOrg/XDPoSChain/core/types"
 	"github.com/XinFinOrg/XDPoSChain/crypto"
 	"github.com/XinFinOrg/XDPoSChain/params"
 )
@@ -143,3 +144,54 @@ func TestSimulatedBackend_EstimateGas(t *testing.T) {
 		}
 	}
 }
+
+// TestSimulatedBackend_EstimateGasAccessList checks that EstimateGas charges the extra
+// intrinsic gas an EIP-2930 access list costs, and that an access-list transfer can be
+// signed and sent through the simulated chain with a non-EIP-155 signer.
+func TestSimulatedBackend_EstimateGasAccessList(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := NewXDCSimulatedBackend(core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}, 10000000, params.TestXDPoSMockChainConfig)
+	defer sim.Close()
+
+	accessList := types.AccessList{{
+		Address:     addr,
+		StorageKeys: []common.Hash{{}},
+	}}
+	msg := XDPoSChain.CallMsg{
+		From:       addr,
+		To:         &addr,
+		Value:      big.NewInt(1),
+		AccessList: accessList,
+	}
+	withList, err := sim.EstimateGas(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("EstimateGas failed: %v", err)
+	}
+	msg.AccessList = nil
+	withoutList, err := sim.EstimateGas(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("EstimateGas failed: %v", err)
+	}
+	if withList <= withoutList {
+		t.Fatalf("expected access list to raise intrinsic gas, got %d <= %d", withList, withoutList)
+	}
+
+	tx, err := types.SignNewTx(key, types.NewEIP2930Signer(params.TestXDPoSMockChainConfig.ChainId), types.NewTx(&types.AccessListTx{
+		ChainID:    params.TestXDPoSMockChainConfig.ChainId,
+		Nonce:      0,
+		To:         &addr,
+		Value:      big.NewInt(1),
+		Gas:        withList,
+		GasPrice:   big.NewInt(0),
+		AccessList: accessList,
+	}))
+	if err != nil {
+		t.Fatalf("failed to sign access-list transaction: %v", err)
+	}
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("SendTransaction failed: %v", err)
+	}
+	sim.Commit()
+}
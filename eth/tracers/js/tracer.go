@@ -0,0 +1,206 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package js exposes a JavaScript tracer runtime that drives the same
+// vm.EVMLogger hooks as the native Go tracers in eth/tracers/native, so a
+// tracer can be supplied ad hoc (e.g. over the debug_traceTransaction RPC)
+// without recompiling the node.
+package js
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+	"github.com/XinFinOrg/XDPoSChain/eth/tracers"
+	"github.com/dop251/goja"
+)
+
+func init() {
+	tracers.RegisterJSTracerConstructor(newJsTracer)
+}
+
+// jsTracer drives a user supplied JavaScript object through the vm.EVMLogger
+// interface, dispatching to whichever of step/fault/result/enter/exit
+// callbacks the object defines. It mirrors upstream go-ethereum's
+// tracers/js package, trimmed to the hooks this chain's EVMLogger exposes.
+type jsTracer struct {
+	vm  *goja.Runtime
+	obj *goja.Object
+
+	step   goja.Callable
+	fault  goja.Callable
+	result goja.Callable
+	enter  goja.Callable
+	exit   goja.Callable
+
+	ctx map[string]interface{} // persistent context object handed to result()
+
+	interrupt uint32
+	reason    error
+	err       error
+}
+
+// newJsTracer compiles code, which must evaluate to an object exposing a
+// subset of {step, fault, result, enter, exit}, and returns a tracers.Tracer
+// driven by it. The cfg argument, if present, is made available to the
+// script as the global `cfg`.
+func newJsTracer(code string, cfg json.RawMessage) (tracers.Tracer, error) {
+	vmjs := goja.New()
+	if cfg == nil {
+		cfg = json.RawMessage("{}")
+	}
+	var cfgVal interface{}
+	if err := json.Unmarshal(cfg, &cfgVal); err != nil {
+		return nil, fmt.Errorf("invalid tracer config: %v", err)
+	}
+	if err := vmjs.Set("cfg", cfgVal); err != nil {
+		return nil, err
+	}
+	ret, err := vmjs.RunString("(" + code + ")")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile tracer: %v", err)
+	}
+	obj, ok := ret.(*goja.Object)
+	if !ok {
+		return nil, errors.New("tracer must evaluate to an object")
+	}
+	t := &jsTracer{vm: vmjs, obj: obj, ctx: make(map[string]interface{})}
+	t.step, _ = goja.AssertFunction(obj.Get("step"))
+	t.fault, _ = goja.AssertFunction(obj.Get("fault"))
+	t.result, _ = goja.AssertFunction(obj.Get("result"))
+	t.enter, _ = goja.AssertFunction(obj.Get("enter"))
+	t.exit, _ = goja.AssertFunction(obj.Get("exit"))
+	if t.result == nil {
+		return nil, errors.New("tracer object must define a result() function")
+	}
+	return t, nil
+}
+
+func (t *jsTracer) call(fn goja.Callable, args ...interface{}) {
+	if fn == nil || atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	jsArgs := make([]goja.Value, len(args))
+	for i, a := range args {
+		jsArgs[i] = t.vm.ToValue(a)
+	}
+	if _, err := fn(t.obj, jsArgs...); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *jsTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.ctx["type"] = map[bool]string{true: "CREATE", false: "CALL"}[create]
+	t.ctx["from"] = from.Hex()
+	t.ctx["to"] = to.Hex()
+	t.ctx["input"] = common.Bytes2Hex(input)
+	t.ctx["gas"] = gas
+	if value != nil {
+		t.ctx["value"] = value.String()
+	}
+}
+
+func (t *jsTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.ctx["output"] = common.Bytes2Hex(output)
+	t.ctx["gasUsed"] = gasUsed
+	if err != nil {
+		t.ctx["error"] = err.Error()
+	}
+}
+
+func (t *jsTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	log := map[string]interface{}{
+		"pc":    pc,
+		"op":    op.String(),
+		"gas":   gas,
+		"cost":  cost,
+		"depth": depth,
+	}
+	if err != nil {
+		log["error"] = err.Error()
+	}
+	t.call(t.step, log)
+}
+
+func (t *jsTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+	t.call(t.fault, map[string]interface{}{
+		"pc":    pc,
+		"op":    op.String(),
+		"gas":   gas,
+		"cost":  cost,
+		"depth": depth,
+		"error": errString(err),
+	})
+}
+
+func (t *jsTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := map[string]interface{}{
+		"type":  typ.String(),
+		"from":  from.Hex(),
+		"to":    to.Hex(),
+		"input": common.Bytes2Hex(input),
+		"gas":   gas,
+	}
+	if value != nil {
+		frame["value"] = value.String()
+	}
+	t.call(t.enter, frame)
+}
+
+func (t *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	res := map[string]interface{}{
+		"output":  common.Bytes2Hex(output),
+		"gasUsed": gasUsed,
+	}
+	if err != nil {
+		res["error"] = err.Error()
+	}
+	t.call(t.exit, res)
+}
+
+func (t *jsTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	ret, err := t.result(t.obj, t.vm.ToValue(t.ctx))
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(ret.Export())
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(encoded), t.reason
+}
+
+func (t *jsTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+	t.vm.Interrupt(err)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -23,24 +23,103 @@ import (
 	"time"
 
 	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/common/hexutil"
 	"github.com/XinFinOrg/XDPoSChain/core/vm"
 	"github.com/XinFinOrg/XDPoSChain/eth/tracers"
+
+	// Force-load the JS tracer engine so its RegisterJSTracerConstructor
+	// init() runs whenever this package (the native tracer registry) does,
+	// the way both are expected to be wired into a running node.
+	_ "github.com/XinFinOrg/XDPoSChain/eth/tracers/js"
 )
 
 func init() {
 	tracers.RegisterNativeTracer("contractTracer", NewContractTracer)
 }
 
+// opMatch records a single occurrence of one of the target opcodes inside a
+// contract's deployed code.
+type opMatch struct {
+	Op string         `json:"op"`
+	PC hexutil.Uint64 `json:"pc"`
+}
+
+// contractInfo is the metadata collected for every contract creation that
+// satisfies the tracer's opcode filter. It marshals to JSON as hex-encoded
+// fields (bytecode, creator) rather than the ad-hoc hex strings the tracer
+// used to build by hand, so RPC clients can decode it straight into typed
+// Go structs.
+type contractInfo struct {
+	Creator  common.Address
+	Type     string // CREATE or CREATE2
+	Depth    int
+	Matches  []opMatch
+	ByteCode []byte // only set if contractTracerConfig.WithByteCode
+}
+
+// contractInfoJSON is the wire format of contractInfo.
+type contractInfoJSON struct {
+	Creator  common.Address `json:"creator"`
+	Type     string         `json:"type"`
+	Depth    int            `json:"depth"`
+	Matches  []opMatch      `json:"matches"`
+	ByteCode hexutil.Bytes  `json:"bytecode,omitempty"`
+}
+
+func (c *contractInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&contractInfoJSON{
+		Creator:  c.Creator,
+		Type:     c.Type,
+		Depth:    c.Depth,
+		Matches:  c.Matches,
+		ByteCode: c.ByteCode,
+	})
+}
+
+func (c *contractInfo) UnmarshalJSON(data []byte) error {
+	var dec contractInfoJSON
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	c.Creator, c.Type, c.Depth, c.Matches, c.ByteCode = dec.Creator, dec.Type, dec.Depth, dec.Matches, dec.ByteCode
+	return nil
+}
+
+// createFrame tracks an in-flight CREATE/CREATE2 (or plain call) until its
+// matching CaptureEnd/CaptureExit, so the tracer can look at the code that was
+// actually deployed before deciding whether it matches.
+type createFrame struct {
+	isCreate bool
+	addr     common.Address
+	creator  common.Address
+	callType string
+	depth    int
+	input    []byte
+}
+
 type contractTracer struct {
-	Addrs     map[string]string
+	Addrs     map[string]*contractInfo
 	config    contractTracerConfig
+	ops       []vm.OpCode
+	stack     []*createFrame
+	depth     int
 	interrupt uint32 // Atomic flag to signal execution interruption
 	reason    error  // Textual reason for the interruption
+
+	// calltree mode only
+	root      *callFrame
+	treeStack []*callFrame
 }
 
+// modeCallTree selects the calltree output format, see contract_calltree.go.
+const modeCallTree = "calltree"
+
 type contractTracerConfig struct {
-	OpCode       string `json:"opCode"`       // Target opcode to trace
-	WithByteCode bool   `json:"withByteCode"` // If true, bytecode will be collected
+	Mode                   string   `json:"mode"`                   // Output mode, "" (default) or "calltree"
+	OpCodes                []string `json:"opCodes"`                // Target opcodes to trace
+	MatchAll               bool     `json:"matchAll"`               // If true, every opcode in OpCodes must appear
+	ExcludeConstructorArgs bool     `json:"excludeConstructorArgs"` // If true, match against the deployed runtime code instead of the raw init code
+	WithByteCode           bool     `json:"withByteCode"`           // If true, bytecode will be collected
 }
 
 // NewContractTracer returns a native go tracer which tracks the contracr was created
@@ -52,24 +131,42 @@ func NewContractTracer(cfg json.RawMessage) (tracers.Tracer, error) {
 		}
 	}
 	t := &contractTracer{
-		Addrs:  make(map[string]string, 1),
+		Addrs:  make(map[string]*contractInfo, 1),
 		config: config,
 	}
-	// handle invalid opcode case
-	op := vm.StringToOp(t.config.OpCode)
-	if op == 0 && t.config.OpCode != "STOP" && t.config.OpCode != "" {
-		t.config.OpCode = "inv"
+	for _, name := range config.OpCodes {
+		// Ignore opcodes that don't resolve to anything, STOP is the only
+		// legitimate zero-value opcode.
+		op := vm.StringToOp(name)
+		if op == 0 && name != "STOP" {
+			continue
+		}
+		t.ops = append(t.ops, op)
 	}
 	return t, nil
 }
 
 func (t *contractTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.depth = 0
 	if create {
-		validateAndStoreOpCode(t, input, to)
+		t.stack = append(t.stack, &createFrame{isCreate: true, addr: to, creator: from, callType: "CREATE", depth: t.depth, input: input})
+	}
+	if t.config.Mode == modeCallTree {
+		t.treeStart(from, to, create, input, gas, value)
 	}
 }
 
 func (t *contractTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	if len(t.stack) != 0 {
+		f := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		if f.isCreate {
+			t.finalize(f, output, err)
+		}
+	}
+	if t.config.Mode == modeCallTree {
+		t.treeEnd(output, gasUsed, err)
+	}
 }
 
 func (t *contractTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
@@ -84,15 +181,66 @@ func (t *contractTracer) CaptureEnter(typ vm.OpCode, from common.Address, to com
 		// TODO: env.Cancel()
 		return
 	}
-	if typ == vm.CREATE || typ == vm.CREATE2 {
-		validateAndStoreOpCode(t, input, to)
+	t.depth++
+	isCreate := typ == vm.CREATE || typ == vm.CREATE2
+	f := &createFrame{isCreate: isCreate, depth: t.depth}
+	if isCreate {
+		f.addr, f.creator, f.callType, f.input = to, from, typ.String(), input
+	}
+	t.stack = append(t.stack, f)
+	if t.config.Mode == modeCallTree {
+		t.treeEnter(typ, from, to, input, gas, value)
 	}
 }
 
 func (t *contractTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.stack) > 0 {
+		f := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		if f.isCreate {
+			t.finalize(f, output, err)
+		}
+	}
+	t.depth--
+	if t.config.Mode == modeCallTree {
+		t.treeExit(output, gasUsed, err)
+	}
+}
+
+// finalize runs the opcode filter over the code belonging to a completed
+// contract creation and, if it matches, records its metadata.
+func (t *contractTracer) finalize(f *createFrame, output []byte, err error) {
+	if err != nil {
+		// Deployment failed, no code was ever stored at f.addr.
+		return
+	}
+	code := f.input
+	if t.config.ExcludeConstructorArgs {
+		// output is the runtime bytecode actually returned by the
+		// constructor, so it can never contain trailing constructor
+		// arguments.
+		code = output
+	}
+	matches, ok := findOpcodes(code, t.ops, t.config.MatchAll)
+	if !ok {
+		return
+	}
+	info := &contractInfo{
+		Creator: f.creator,
+		Type:    f.callType,
+		Depth:   f.depth,
+		Matches: matches,
+	}
+	if t.config.WithByteCode {
+		info.ByteCode = code
+	}
+	t.Addrs[addrToHex(f.addr)] = info
 }
 
 func (t *contractTracer) GetResult() (json.RawMessage, error) {
+	if t.config.Mode == modeCallTree {
+		return t.treeResult()
+	}
 	res, err := json.Marshal(t.Addrs)
 	if err != nil {
 		return nil, err
@@ -105,30 +253,48 @@ func (t *contractTracer) Stop(err error) {
 	atomic.StoreUint32(&t.interrupt, 1)
 }
 
-func validateAndStoreOpCode(t *contractTracer, input []byte, to common.Address) {
-	// If the OpCode is "inv" or if the OpCode is not empty and doesn't match the input, exit early.
-	if t.config.OpCode == "inv" || (t.config.OpCode != "" && !findOpcodes(input, vm.StringToOp(t.config.OpCode))) {
-		return
+// findOpcodes scans bytecode for occurrences of the given target opcodes,
+// skipping PUSH instructions (including PUSH0, EIP-3855) and their immediate
+// data. It returns the matches found along with whether the code satisfies
+// the filter: when matchAll is set every target opcode must occur at least
+// once, otherwise any single occurrence is enough. An empty target list
+// matches unconditionally (with no recorded matches), preserving the
+// tracer's previous "trace every creation" default.
+func findOpcodes(bytecode []byte, targets []vm.OpCode, matchAll bool) ([]opMatch, bool) {
+	if len(targets) == 0 {
+		return nil, true
 	}
-	// If WithByteCode is true, store the input in the address mapping as hex.
-	if t.config.WithByteCode {
-		t.Addrs[addrToHex(to)] = bytesToHex(input)
-	} else {
-		t.Addrs[addrToHex(to)] = ""
+	want := make(map[vm.OpCode]bool, len(targets))
+	for _, op := range targets {
+		want[op] = true
 	}
-}
-
-// Compare bytecode with the given opcode, skipping PUSH instructions.
-func findOpcodes(bytecode []byte, opcode vm.OpCode) bool {
+	found := make(map[vm.OpCode]bool, len(targets))
+	var matches []opMatch
 	for i := 0; i < len(bytecode); {
 		op := vm.OpCode(bytecode[i])
-		// Skip PUSH opcodes and their arguments
-		if op.IsPush() {
-			i += int(op - 95) // Directly calculate the number of bytes to skip
-		} else if op == opcode {
-			return true
+		if want[op] {
+			found[op] = true
+			matches = append(matches, opMatch{Op: op.String(), PC: hexutil.Uint64(i)})
+		}
+		if op.IsPush() && op != vm.PUSH0 {
+			// Skip the immediate PUSH data, guarding against an init code
+			// that ends mid-instruction.
+			n := int(op - vm.PUSH1 + 1)
+			if i+1+n > len(bytecode) {
+				break
+			}
+			i += 1 + n
+			continue
 		}
 		i++
 	}
-	return false
+	if matchAll {
+		for _, op := range targets {
+			if !found[op] {
+				return nil, false
+			}
+		}
+		return matches, true
+	}
+	return matches, len(matches) > 0
 }
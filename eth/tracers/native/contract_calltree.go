@@ -0,0 +1,195 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/common/hexutil"
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+)
+
+// callFrame is a callTracer-compatible node in the nested call tree built by
+// contractTracer when contractTracerConfig.Mode is "calltree".
+type callFrame struct {
+	Type    string
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	Error   string
+	Calls   []*callFrame
+
+	isCreate bool           // whether this frame deployed code
+	addr     common.Address // address of the deployed code, if isCreate
+}
+
+// callFrameJSON is the wire format of callFrame, matching upstream's
+// callTracer: gas-like fields are hex quantities, byte blobs are hex strings,
+// and the value is a hex big int.
+type callFrameJSON struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big   `json:"value,omitempty"`
+	Gas     hexutil.Uint64 `json:"gas"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []*callFrame   `json:"calls,omitempty"`
+}
+
+func (f *callFrame) MarshalJSON() ([]byte, error) {
+	enc := &callFrameJSON{
+		Type:    f.Type,
+		From:    f.From,
+		To:      f.To,
+		Gas:     hexutil.Uint64(f.Gas),
+		GasUsed: hexutil.Uint64(f.GasUsed),
+		Input:   f.Input,
+		Output:  f.Output,
+		Error:   f.Error,
+		Calls:   f.Calls,
+	}
+	if f.Value != nil {
+		enc.Value = (*hexutil.Big)(f.Value)
+	}
+	return json.Marshal(enc)
+}
+
+func (f *callFrame) UnmarshalJSON(data []byte) error {
+	var dec callFrameJSON
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	f.Type, f.From, f.To = dec.Type, dec.From, dec.To
+	f.Gas, f.GasUsed = uint64(dec.Gas), uint64(dec.GasUsed)
+	f.Input, f.Output, f.Error, f.Calls = dec.Input, dec.Output, dec.Error, dec.Calls
+	if dec.Value != nil {
+		f.Value = (*big.Int)(dec.Value)
+	}
+	return nil
+}
+
+func (t *contractTracer) treeStart(from, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.root = &callFrame{
+		Type:     map[bool]string{true: "CREATE", false: "CALL"}[create],
+		From:     from,
+		To:       to,
+		Value:    value,
+		Gas:      gas,
+		Input:    input,
+		isCreate: create,
+		addr:     to,
+	}
+	t.treeStack = []*callFrame{t.root}
+}
+
+func (t *contractTracer) treeEnter(typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if len(t.treeStack) == 0 {
+		return
+	}
+	isCreate := typ == vm.CREATE || typ == vm.CREATE2
+	f := &callFrame{
+		Type:     typ.String(),
+		From:     from,
+		To:       to,
+		Value:    value,
+		Gas:      gas,
+		Input:    input,
+		isCreate: isCreate,
+		addr:     to,
+	}
+	parent := t.treeStack[len(t.treeStack)-1]
+	parent.Calls = append(parent.Calls, f)
+	t.treeStack = append(t.treeStack, f)
+}
+
+func (t *contractTracer) treeExit(output []byte, gasUsed uint64, err error) {
+	if len(t.treeStack) == 0 {
+		return
+	}
+	f := t.treeStack[len(t.treeStack)-1]
+	t.treeStack = t.treeStack[:len(t.treeStack)-1]
+	t.fillFrame(f, output, gasUsed, err)
+}
+
+func (t *contractTracer) treeEnd(output []byte, gasUsed uint64, err error) {
+	if len(t.treeStack) == 0 {
+		return
+	}
+	f := t.treeStack[len(t.treeStack)-1]
+	t.treeStack = t.treeStack[:len(t.treeStack)-1]
+	t.fillFrame(f, output, gasUsed, err)
+}
+
+func (t *contractTracer) fillFrame(f *callFrame, output []byte, gasUsed uint64, err error) {
+	f.GasUsed = gasUsed
+	f.Output = output
+	if err != nil {
+		f.Error = err.Error()
+	}
+}
+
+// treeResult marshals the call tree built during execution, pruned (when an
+// opcode filter is configured) to only the frames that deployed code
+// containing one of the target opcodes, and their ancestors.
+func (t *contractTracer) treeResult() (json.RawMessage, error) {
+	root := t.root
+	if root != nil && len(t.ops) > 0 {
+		root, _ = pruneCallTree(root, t.ops, t.config.MatchAll, t.config.ExcludeConstructorArgs)
+	}
+	res, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// pruneCallTree keeps a frame if it (or any descendant) deployed code
+// matching the opcode filter, dropping every other branch.
+func pruneCallTree(f *callFrame, ops []vm.OpCode, matchAll, excludeCtorArgs bool) (*callFrame, bool) {
+	kept := *f
+	kept.Calls = nil
+
+	matched := false
+	if f.isCreate && f.Error == "" {
+		code := f.Input
+		if excludeCtorArgs {
+			code = f.Output
+		}
+		if _, ok := findOpcodes(code, ops, matchAll); ok {
+			matched = true
+		}
+	}
+	for _, child := range f.Calls {
+		if prunedChild, ok := pruneCallTree(child, ops, matchAll, excludeCtorArgs); ok {
+			kept.Calls = append(kept.Calls, prunedChild)
+			matched = true
+		}
+	}
+	if !matched {
+		return nil, false
+	}
+	return &kept, true
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/XinFinOrg/XDPoSChain"
+	"github.com/XinFinOrg/XDPoSChain/accounts/abi"
+	"github.com/XinFinOrg/XDPoSChain/accounts/abi/bind"
+	"github.com/XinFinOrg/XDPoSChain/accounts/abi/bind/backends"
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/core"
+	"github.com/XinFinOrg/XDPoSChain/crypto"
+	"github.com/XinFinOrg/XDPoSChain/params"
+)
+
+// Same GasEstimation contract backends.TestSimulatedBackend_EstimateGas deploys,
+// so the "Valid" call's real gasUsed (21275, per that test's expectation table)
+// can be cross-checked against what contractTracer's calltree actually recorded
+// for the same call on the same simulated chain.
+const (
+	gasEstimationContractAbi = "[{\"inputs\":[],\"name\":\"Assert\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"OOG\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"PureRevert\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"Revert\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"Valid\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+	gasEstimationContractBin = "0x608060405234801561001057600080fd5b50610156806100206000396000f3fe608060405234801561001057600080fd5b50600436106100575760003560e01c806350f6fe341461005c578063aa8b1d3014610066578063b9b046f914610070578063d8b983911461007a578063e09fface14610084575b600080fd5b61006461008e565b005b61006e6100a1565b005b6100786100a6565b005b6100826100b0565b005b61008c61011e565b005b60008090505b8080600101915050610094565b600080fd5b60006100ae57fe5b565b6040517f08c379a000000000000000000000000000000000000000000000000000000000815260040180806020018281038252600d8152602001807f72657665727420726561736f6e0000000000000000000000000000000000000081525060200191505060405180910390fd5b56fea26469706673582212206f8c043de30823c47c0df44a4404868a45bd4fbb4ff8846a6e1f476d79d3297764736f6c63430006040033"
+)
+
+// TestContractTracerAgainstSimulatedBackend attaches a calltree contractTracer
+// to a real backends.SimulatedBackend call (via SimulatedBackend.SetTracer)
+// and checks the recorded tree's gasUsed against the 21275 that
+// backends.TestSimulatedBackend_EstimateGas's "Valid" case expects for the
+// exact same contract and call data. It only covers calls executed through
+// SimulatedBackend.PendingCallContract/CallContract/EstimateGas; a deployment
+// sent and Commit()ed as a real transaction is replayed by the blockchain's
+// block processor, which has no tracer hook (see SetTracer's doc comment),
+// so the deployment itself isn't traced here.
+func TestContractTracerAgainstSimulatedBackend(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	opts := bind.NewKeyedTransactor(key)
+
+	sim := backends.NewXDCSimulatedBackend(core.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}}, 10000000, params.TestXDPoSMockChainConfig)
+	defer sim.Close()
+
+	parsed, err := abi.JSON(strings.NewReader(gasEstimationContractAbi))
+	if err != nil {
+		t.Fatalf("failed to parse contract ABI: %v", err)
+	}
+	contractAddr, _, _, err := bind.DeployContract(opts, parsed, common.FromHex(gasEstimationContractBin), sim)
+	if err != nil {
+		t.Fatalf("DeployContract failed: %v", err)
+	}
+	sim.Commit()
+
+	tr := newCallTreeTracer(t, contractTracerConfig{Mode: "calltree"})
+	sim.SetTracer(tr)
+	defer sim.SetTracer(nil)
+
+	_, err = sim.PendingCallContract(context.Background(), XDPoSChain.CallMsg{
+		From:     addr,
+		To:       &contractAddr,
+		Gas:      100000,
+		GasPrice: big.NewInt(0),
+		Data:     common.Hex2Bytes("e09fface"), // Valid()
+	})
+	if err != nil {
+		t.Fatalf("PendingCallContract failed: %v", err)
+	}
+
+	res, err := tr.GetResult()
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	var frame callFrame
+	if err := json.Unmarshal(res, &frame); err != nil {
+		t.Fatalf("failed to unmarshal call tree: %v", err)
+	}
+	if frame.Type != "CALL" || frame.To != contractAddr {
+		t.Fatalf("unexpected root frame: %+v", frame)
+	}
+	if frame.GasUsed != 21275 {
+		t.Fatalf("expected gasUsed 21275 (matching TestSimulatedBackend_EstimateGas's Valid case), got %d", frame.GasUsed)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+)
+
+var (
+	deployer = common.HexToAddress("0x1000000000000000000000000000000000000a")
+	contract = common.HexToAddress("0x2000000000000000000000000000000000000b")
+	helper   = common.HexToAddress("0x3000000000000000000000000000000000000c")
+)
+
+func newCallTreeTracer(t *testing.T, cfg contractTracerConfig) *contractTracer {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	tr, err := NewContractTracer(raw)
+	if err != nil {
+		t.Fatalf("NewContractTracer failed: %v", err)
+	}
+	return tr.(*contractTracer)
+}
+
+// TestContractTracerCallTreeShape drives a root CREATE followed by a nested
+// CALL, the way the EVM would for a constructor that calls out to an already
+// deployed helper before returning its runtime code, and checks that the
+// resulting call tree nests the CALL frame under the CREATE root with the
+// right fields rather than flattening it.
+func TestContractTracerCallTreeShape(t *testing.T) {
+	root := common.FromHex("0x6000600055")
+	call := common.FromHex("0x6001600155")
+	callOutput := common.FromHex("0x01")
+
+	tr := newCallTreeTracer(t, contractTracerConfig{Mode: "calltree"})
+
+	tr.CaptureStart(nil, deployer, contract, true, root, 1_000_000, big.NewInt(0))
+	tr.CaptureEnter(vm.CALL, contract, helper, call, 50_000, big.NewInt(7))
+	tr.CaptureExit(callOutput, 21_000, nil)
+	tr.CaptureEnd(root, 85_275, 0, nil)
+
+	res, err := tr.GetResult()
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	var frame callFrame
+	if err := json.Unmarshal(res, &frame); err != nil {
+		t.Fatalf("failed to unmarshal call tree: %v", err)
+	}
+	if frame.Type != "CREATE" || frame.To != contract {
+		t.Fatalf("unexpected root frame: %+v", frame)
+	}
+	if frame.GasUsed != 85_275 {
+		t.Fatalf("expected root gasUsed 85275, got %d", frame.GasUsed)
+	}
+	if len(frame.Calls) != 1 {
+		t.Fatalf("expected 1 nested call, got %d", len(frame.Calls))
+	}
+	child := frame.Calls[0]
+	if child.Type != "CALL" || child.From != contract || child.To != helper {
+		t.Fatalf("unexpected child frame: %+v", child)
+	}
+	if child.GasUsed != 21_000 || string(child.Output) != string(callOutput) {
+		t.Fatalf("child frame did not record its CaptureExit result: %+v", child)
+	}
+}
+
+// TestContractTracerCallTreePrune verifies that opcode-filtered calltree mode
+// keeps only the branch that deployed matching code (and its ancestors),
+// dropping sibling branches that didn't, across a multi-frame tree.
+func TestContractTracerCallTreePrune(t *testing.T) {
+	matchingCode := []byte{byte(vm.SELFDESTRUCT)}
+	plainCode := []byte{byte(vm.STOP)}
+
+	// No opcode filter: the whole tree is always kept.
+	plain := newCallTreeTracer(t, contractTracerConfig{Mode: "calltree"})
+	plain.CaptureStart(nil, deployer, contract, true, plainCode, 1_000_000, big.NewInt(0))
+	plain.CaptureEnter(vm.CREATE, contract, helper, matchingCode, 50_000, big.NewInt(0))
+	plain.CaptureExit(matchingCode, 21_000, nil)
+	plain.CaptureEnd(plainCode, 71_000, 0, nil)
+	if plain.root == nil || len(plain.root.Calls) != 1 {
+		t.Fatalf("expected an unpruned root with 1 child, got %+v", plain.root)
+	}
+
+	// A filter for SELFDESTRUCT: only the child that deployed matching code
+	// survives, dragging its non-matching CREATE root along as an ancestor.
+	filtered := newCallTreeTracer(t, contractTracerConfig{Mode: "calltree", OpCodes: []string{"SELFDESTRUCT"}})
+	filtered.CaptureStart(nil, deployer, contract, true, plainCode, 1_000_000, big.NewInt(0))
+	filtered.CaptureEnter(vm.CREATE, contract, helper, matchingCode, 50_000, big.NewInt(0))
+	filtered.CaptureExit(matchingCode, 21_000, nil)
+	filtered.CaptureEnd(plainCode, 71_000, 0, nil)
+
+	res, err := filtered.GetResult()
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	var frame callFrame
+	if err := json.Unmarshal(res, &frame); err != nil {
+		t.Fatalf("failed to unmarshal pruned call tree: %v", err)
+	}
+	if frame.To != contract {
+		t.Fatalf("expected the root to survive as the matching child's ancestor, got %+v", frame)
+	}
+	if len(frame.Calls) != 1 || frame.Calls[0].To != helper {
+		t.Fatalf("expected only the matching CREATE child to survive, got %+v", frame.Calls)
+	}
+
+	// A filter for an opcode that never appears anywhere in the tree prunes
+	// everything away, including the root.
+	empty := newCallTreeTracer(t, contractTracerConfig{Mode: "calltree", OpCodes: []string{"SELFDESTRUCT"}})
+	empty.CaptureStart(nil, deployer, contract, true, plainCode, 1_000_000, big.NewInt(0))
+	empty.CaptureEnter(vm.CREATE, contract, helper, plainCode, 50_000, big.NewInt(0))
+	empty.CaptureExit(plainCode, 21_000, nil)
+	empty.CaptureEnd(plainCode, 71_000, 0, nil)
+	res, err = empty.GetResult()
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	if string(res) != "null" {
+		t.Fatalf("expected fully pruned tree to be null, got %s", res)
+	}
+}
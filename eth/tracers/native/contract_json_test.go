@@ -0,0 +1,87 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/XinFinOrg/XDPoSChain/common"
+)
+
+func TestContractInfoJSONRoundTrip(t *testing.T) {
+	want := &contractInfo{
+		Creator:  common.HexToAddress("0x1000000000000000000000000000000000000a"),
+		Type:     "CREATE2",
+		Depth:    3,
+		Matches:  []opMatch{{Op: "SELFDESTRUCT", PC: 12}},
+		ByteCode: []byte{0x60, 0x00},
+	}
+	enc, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if got := string(enc); got[0] != '{' {
+		t.Fatalf("expected a JSON object, got %s", got)
+	}
+
+	var got contractInfo
+	if err := json.Unmarshal(enc, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.Creator != want.Creator || got.Type != want.Type || got.Depth != want.Depth {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", got, want)
+	}
+	if len(got.Matches) != 1 || got.Matches[0].Op != "SELFDESTRUCT" || got.Matches[0].PC != 12 {
+		t.Fatalf("matches round-trip mismatch: got %+v", got.Matches)
+	}
+	if string(got.ByteCode) != string(want.ByteCode) {
+		t.Fatalf("bytecode round-trip mismatch: got %x, want %x", got.ByteCode, want.ByteCode)
+	}
+}
+
+func TestCallFrameJSONRoundTrip(t *testing.T) {
+	want := &callFrame{
+		Type:    "CALL",
+		From:    common.HexToAddress("0x1000000000000000000000000000000000000a"),
+		To:      common.HexToAddress("0x2000000000000000000000000000000000000b"),
+		Value:   big.NewInt(42),
+		Gas:     100000,
+		GasUsed: 21000,
+		Input:   []byte{0xaa, 0xbb},
+		Output:  []byte{0xcc},
+	}
+	enc, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got callFrame
+	if err := json.Unmarshal(enc, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.Type != want.Type || got.From != want.From || got.To != want.To {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", got, want)
+	}
+	if got.Value.Cmp(want.Value) != 0 || got.Gas != want.Gas || got.GasUsed != want.GasUsed {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", got, want)
+	}
+	if string(got.Input) != string(want.Input) || string(got.Output) != string(want.Output) {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracers is a collection of JSON-RPC transaction/call tracers. A tracer may
+// either be implemented natively in Go (registered with RegisterNativeTracer) or
+// supplied on the fly as a small JavaScript program (see the js subpackage).
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+)
+
+// Tracer interface extends vm.EVMLogger and additionally
+// allows collecting the tracing result.
+type Tracer interface {
+	vm.EVMLogger
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// ctorFn is the constructor signature of a native Go tracer.
+type ctorFn func(cfg json.RawMessage) (Tracer, error)
+
+// jsCtorFn is the constructor signature of a JS tracer, see the js subpackage.
+type jsCtorFn func(code string, cfg json.RawMessage) (Tracer, error)
+
+var (
+	nativeTracers = make(map[string]ctorFn)
+	jsTracerCtor  jsCtorFn
+)
+
+// RegisterNativeTracer registers a method as a lookup for tracers, meant for
+// tracers implemented in native Go.
+func RegisterNativeTracer(name string, ctor ctorFn) {
+	nativeTracers[name] = ctor
+}
+
+// RegisterJSTracerConstructor installs the constructor used to compile inline
+// JavaScript tracer bodies. It is called from the js subpackage's init so
+// that this package does not need to import it directly (which would create
+// an import cycle, since js tracers are driven through this package's Tracer
+// interface).
+func RegisterJSTracerConstructor(ctor jsCtorFn) {
+	jsTracerCtor = ctor
+}
+
+// New returns a new instance of a tracer, either a native Go tracer registered
+// under name, or, if name isn't a registered native tracer, an inline
+// JavaScript tracer body compiled on the fly.
+func New(name string, cfg json.RawMessage) (Tracer, error) {
+	if ctor, ok := nativeTracers[name]; ok {
+		return ctor(cfg)
+	}
+	if jsTracerCtor != nil {
+		return jsTracerCtor(name, cfg)
+	}
+	return nil, errors.New("tracer not found")
+}
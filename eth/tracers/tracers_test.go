@@ -0,0 +1,69 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/XinFinOrg/XDPoSChain/common"
+	"github.com/XinFinOrg/XDPoSChain/core/vm"
+	"github.com/XinFinOrg/XDPoSChain/eth/tracers"
+
+	// Registers the JS tracer constructor used by New below.
+	_ "github.com/XinFinOrg/XDPoSChain/eth/tracers/js"
+)
+
+// TestNewDispatchesInlineJSTracer checks that tracers.New falls back to
+// compiling name as an inline JavaScript tracer body when it isn't the name
+// of a registered native tracer, end to end through the real vm.EVMLogger
+// hooks a node would drive it with.
+func TestNewDispatchesInlineJSTracer(t *testing.T) {
+	tr, err := tracers.New(`{
+		step: function(log) { this.steps = (this.steps || 0) + 1 },
+		fault: function() {},
+		result: function(ctx) { return {from: ctx.from, steps: this.steps} }
+	}`, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	from := common.HexToAddress("0x1000000000000000000000000000000000000a")
+	to := common.HexToAddress("0x2000000000000000000000000000000000000b")
+	tr.CaptureStart(nil, from, to, false, nil, 100000, big.NewInt(0))
+	tr.CaptureState(nil, 0, vm.PUSH1, 3, 3, nil, nil, 0, nil)
+	tr.CaptureState(nil, 2, vm.PUSH1, 3, 3, nil, nil, 0, nil)
+	tr.CaptureEnd(nil, 6, 0, nil)
+
+	res, err := tr.GetResult()
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	want := fmt.Sprintf(`{"from":%q,"steps":2}`, from.Hex())
+	if string(res) != want {
+		t.Fatalf("unexpected result: got %s, want %s", res, want)
+	}
+}
+
+// TestNewUnknownTracer checks that New still reports an error for a name
+// that is neither a registered native tracer nor valid JavaScript.
+func TestNewUnknownTracer(t *testing.T) {
+	if _, err := tracers.New("not valid js {{{", nil); err == nil {
+		t.Fatal("expected an error for an invalid tracer body")
+	}
+}